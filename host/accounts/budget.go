@@ -21,11 +21,25 @@ type (
 		// Spend subtracts amount from the remaining budget. An error is
 		// returned if their are insufficient funds.
 		Spend(amount types.Currency) error
+		// Sub carves off a child budget with max equal to amount, debiting it
+		// from the remaining budget. The child can be committed or rolled
+		// back independently of its parent; neither touches the account
+		// store until the root budget commits. The budget cannot be
+		// Committed or Rolled back while a child carved off via Sub is
+		// still open.
+		Sub(amount types.Currency) (Budget, error)
+		// Savepoint returns a closure that, when called, rolls back any
+		// spending recorded on the budget since Savepoint was called. The
+		// closure panics if called after a Sub carved off or folded back a
+		// child in the interim.
+		Savepoint() func()
 		// Rollback rolls back the budget's spending. If the budget has already
-		// been committed, Rollback is a no-op.
+		// been committed or rolled back, Rollback is a no-op. Rollback returns
+		// an error if a child carved off via Sub is still open.
 		Rollback() error
 		// Commit commits the budget's spending to the account. If the budget
-		// has already been committed, Commit will panic.
+		// has already been committed, Commit will panic. Commit returns an
+		// error if a child carved off via Sub is still open.
 		Commit() error
 	}
 
@@ -35,6 +49,39 @@ type (
 		spent     types.Currency
 		committed bool
 		am        *AccountManager
+
+		// openChildren counts sub-budgets carved off via Sub that have not
+		// yet been Committed or Rolled back. A budget cannot be Committed or
+		// Rolled back while openChildren is non-zero.
+		openChildren int
+		// childEvents counts every Sub and every child Commit/Rollback,
+		// monotonically. Unlike openChildren, it never returns to a prior
+		// value once a child has opened or closed, so Savepoint can detect a
+		// balanced Sub/Commit pair that nets openChildren back to its
+		// original value while it was in scope.
+		childEvents int
+	}
+
+	// A subBudget is a Budget carved off of a parent Budget's remaining
+	// funds. Its Commit and Rollback fold its spending back into the parent
+	// instead of touching the account store directly; only the root budget's
+	// Commit ever debits the account.
+	subBudget struct {
+		parent    budgetParent
+		max       types.Currency
+		spent     types.Currency
+		committed bool
+
+		openChildren int
+		childEvents  int
+	}
+
+	// budgetParent is the subset of Budget a sub-budget needs from whatever
+	// it was carved out of, so that Commit or Rollback can fold its spending
+	// back into the parent and release the parent's carve-out bookkeeping.
+	budgetParent interface {
+		Refund(amount types.Currency)
+		childClosed()
 	}
 )
 
@@ -80,12 +127,54 @@ func (b *budget) Spend(amount types.Currency) error {
 	return nil
 }
 
+// Sub carves off a child budget with max equal to amount, debiting it from
+// the remaining budget. The child can be committed or rolled back
+// independently; neither touches the account store until the root budget
+// commits. The root cannot be Committed or Rolled back while the child is
+// still open.
+func (b *budget) Sub(amount types.Currency) (Budget, error) {
+	if b.committed {
+		panic("budget already committed")
+	}
+	if err := b.Spend(amount); err != nil {
+		return nil, err
+	}
+	b.openChildren++
+	b.childEvents++
+	return &subBudget{parent: b, max: amount}, nil
+}
+
+// Savepoint returns a closure that, when called, rolls back any spending
+// recorded on the budget since Savepoint was called. The closure panics if a
+// sub-budget carved off via Sub is opened or closed between the call to
+// Savepoint and the call to the returned closure, since rolling back spent
+// would desynchronize it from any still-open or already-folded-back child.
+func (b *budget) Savepoint() func() {
+	spent := b.spent
+	childEvents := b.childEvents
+	return func() {
+		if b.childEvents != childEvents {
+			panic("budget: cannot rollback to savepoint across a Sub call")
+		}
+		b.spent = spent
+	}
+}
+
+func (b *budget) childClosed() {
+	b.openChildren--
+	b.childEvents++
+}
+
 // Rollback returns the amount spent back to the account. If the budget has
-// already been committed, Rollback is a no-op.
+// already been committed or rolled back, Rollback is a no-op.
 func (b *budget) Rollback() error {
 	if b.committed {
 		return nil
+	} else if b.openChildren > 0 {
+		return fmt.Errorf("cannot rollback: %d sub-budget(s) still open", b.openChildren)
 	}
+	b.committed = true
+
 	b.am.mu.Lock()
 	defer b.am.mu.Unlock()
 
@@ -111,6 +200,8 @@ func (b *budget) Rollback() error {
 func (b *budget) Commit() error {
 	if b.committed {
 		panic("budget already committed")
+	} else if b.openChildren > 0 {
+		return fmt.Errorf("cannot commit: %d sub-budget(s) still open", b.openChildren)
 	}
 	// debit the account
 	_, err := b.am.store.Debit(b.accountID, b.spent)
@@ -142,4 +233,110 @@ func (b *budget) Commit() error {
 	state.balance = state.balance.Add(b.max.Sub(rem))
 	b.am.balances[b.accountID] = state
 	return nil
-}
\ No newline at end of file
+}
+
+// Remaining returns the amount remaining in the budget
+func (b *subBudget) Remaining() types.Currency {
+	return b.max.Sub(b.spent)
+}
+
+// Empty spends all of the remaining budget and returns the amount spent
+func (b *subBudget) Empty() (spent types.Currency) {
+	if b.committed {
+		panic("budget already committed")
+	}
+	spent, b.spent = b.spent, b.max
+	return
+}
+
+// Refund returns amount back to the budget. Refund will panic if the budget has
+// already been committed or the refund is greater than the amount spent.
+func (b *subBudget) Refund(amount types.Currency) {
+	if b.committed {
+		panic("budget already committed")
+	} else if amount.Cmp(b.spent) > 0 {
+		panic("cannot refund more than spent")
+	}
+	b.spent = b.spent.Sub(amount)
+}
+
+// Spend subtracts amount from the remaining budget. An error is returned if
+// their are insufficient funds.
+func (b *subBudget) Spend(amount types.Currency) error {
+	spent := b.spent.Add(amount)
+	if b.max.Cmp(spent) < 0 {
+		return fmt.Errorf("unable to spend %v, %v remaining: %w", amount, b.max.Sub(b.spent), ErrInsufficientBudget)
+	}
+	b.spent = spent
+	return nil
+}
+
+// Sub carves off a grandchild budget with max equal to amount, debiting it
+// from the remaining budget. This budget cannot be Committed or Rolled back
+// while the grandchild is still open.
+func (b *subBudget) Sub(amount types.Currency) (Budget, error) {
+	if b.committed {
+		panic("budget already committed")
+	}
+	if err := b.Spend(amount); err != nil {
+		return nil, err
+	}
+	b.openChildren++
+	b.childEvents++
+	return &subBudget{parent: b, max: amount}, nil
+}
+
+// Savepoint returns a closure that, when called, rolls back any spending
+// recorded on the budget since Savepoint was called. The closure panics if a
+// sub-budget carved off via Sub is opened or closed between the call to
+// Savepoint and the call to the returned closure, since rolling back spent
+// would desynchronize it from any still-open or already-folded-back child.
+func (b *subBudget) Savepoint() func() {
+	spent := b.spent
+	childEvents := b.childEvents
+	return func() {
+		if b.childEvents != childEvents {
+			panic("budget: cannot rollback to savepoint across a Sub call")
+		}
+		b.spent = spent
+	}
+}
+
+func (b *subBudget) childClosed() {
+	b.openChildren--
+	b.childEvents++
+}
+
+// Rollback returns the amount carved off from the parent budget. If the
+// budget has already been committed or rolled back, Rollback is a no-op.
+func (b *subBudget) Rollback() error {
+	if b.committed {
+		return nil
+	} else if b.openChildren > 0 {
+		return fmt.Errorf("cannot rollback: %d sub-budget(s) still open", b.openChildren)
+	}
+	// return the entire carve-out to the parent; none of this budget's
+	// spending is real until it is committed.
+	b.parent.Refund(b.max)
+	b.parent.childClosed()
+	b.max, b.spent = types.ZeroCurrency, types.ZeroCurrency
+	b.committed = true
+	return nil
+}
+
+// Commit folds the budget's spending back into the parent. If the budget has
+// already been committed, Commit will panic.
+func (b *subBudget) Commit() error {
+	if b.committed {
+		panic("budget already committed")
+	} else if b.openChildren > 0 {
+		return fmt.Errorf("cannot commit: %d sub-budget(s) still open", b.openChildren)
+	}
+	// only the unspent remainder of the carve-out is returned to the
+	// parent; the rest has genuinely been spent.
+	b.parent.Refund(b.max.Sub(b.spent))
+	b.parent.childClosed()
+	b.max, b.spent = types.ZeroCurrency, types.ZeroCurrency
+	b.committed = true
+	return nil
+}
@@ -0,0 +1,154 @@
+package accounts
+
+import (
+	"testing"
+
+	"go.sia.tech/siad/types"
+)
+
+func TestBudgetSubCommit(t *testing.T) {
+	root := &budget{max: types.NewCurrency64(100)}
+
+	child, err := root.Sub(types.NewCurrency64(40))
+	if err != nil {
+		t.Fatal(err)
+	} else if r := root.Remaining(); r.Cmp(types.NewCurrency64(60)) != 0 {
+		t.Fatalf("expected 60 remaining in root, got %v", r)
+	}
+
+	if err := child.Spend(types.NewCurrency64(10)); err != nil {
+		t.Fatal(err)
+	} else if r := child.Remaining(); r.Cmp(types.NewCurrency64(30)) != 0 {
+		t.Fatalf("expected 30 remaining in child, got %v", r)
+	}
+
+	// spending beyond the carve-out fails even though the root has plenty
+	// of its own funds remaining
+	if err := child.Spend(types.NewCurrency64(31)); err == nil {
+		t.Fatal("expected insufficient budget error")
+	}
+
+	if err := child.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	// only the 10 actually spent by the child should remain debited from
+	// the root; the other 30 of the carve-out must be returned
+	if root.spent.Cmp(types.NewCurrency64(10)) != 0 {
+		t.Fatalf("expected root to have 10 spent after child commit, got %v", root.spent)
+	} else if root.openChildren != 0 {
+		t.Fatalf("expected root to have 0 open children, got %v", root.openChildren)
+	}
+}
+
+func TestBudgetSubRollback(t *testing.T) {
+	root := &budget{max: types.NewCurrency64(100)}
+
+	child, err := root.Sub(types.NewCurrency64(40))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := child.Spend(types.NewCurrency64(25)); err != nil {
+		t.Fatal(err)
+	}
+	if err := child.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	// rollback must return the entire carve-out, not just the unspent
+	// remainder
+	if root.spent.Cmp(types.ZeroCurrency) != 0 {
+		t.Fatalf("expected root to have 0 spent after child rollback, got %v", root.spent)
+	}
+}
+
+func TestBudgetCommitBlockedByOpenChild(t *testing.T) {
+	root := &budget{max: types.NewCurrency64(100)}
+
+	if _, err := root.Sub(types.NewCurrency64(40)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := root.Commit(); err == nil {
+		t.Fatal("expected commit to fail while a sub-budget is still open")
+	}
+	if err := root.Rollback(); err == nil {
+		t.Fatal("expected rollback to fail while a sub-budget is still open")
+	}
+}
+
+func TestBudgetSavepointAcrossSub(t *testing.T) {
+	root := &budget{max: types.NewCurrency64(100)}
+
+	rollback := root.Savepoint()
+	if _, err := root.Sub(types.NewCurrency64(40)); err != nil {
+		t.Fatal(err)
+	}
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatal("expected rollback to panic after an intervening Sub")
+		}
+	}()
+	rollback()
+}
+
+func TestBudgetSavepointAcrossBalancedSub(t *testing.T) {
+	root := &budget{max: types.NewCurrency64(100)}
+
+	rollback := root.Savepoint()
+	child, err := root.Sub(types.NewCurrency64(40))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := child.Spend(types.NewCurrency64(10)); err != nil {
+		t.Fatal(err)
+	}
+	if err := child.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	// root.openChildren is back to 0, matching its value when Savepoint was
+	// called, but the child's spend is now folded into root.spent; the
+	// savepoint must still detect the intervening Sub/Commit and panic
+	// rather than silently discarding it
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatal("expected rollback to panic after a balanced Sub/Commit")
+		}
+	}()
+	rollback()
+}
+
+func TestBudgetRollbackIdempotent(t *testing.T) {
+	root := &budget{max: types.NewCurrency64(100)}
+
+	child, err := root.Sub(types.NewCurrency64(40))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := child.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	if root.openChildren != 0 {
+		t.Fatalf("expected 0 open children after rollback, got %v", root.openChildren)
+	}
+	// a second Rollback must not fold the carve-out back into the parent
+	// again
+	if err := child.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	if root.openChildren != 0 {
+		t.Fatalf("expected 0 open children after redundant rollback, got %v", root.openChildren)
+	}
+}
+
+func TestBudgetSavepointWithoutSub(t *testing.T) {
+	root := &budget{max: types.NewCurrency64(100)}
+
+	rollback := root.Savepoint()
+	if err := root.Spend(types.NewCurrency64(40)); err != nil {
+		t.Fatal(err)
+	}
+	rollback()
+	if root.spent.Cmp(types.ZeroCurrency) != 0 {
+		t.Fatalf("expected spend to be rolled back, got %v spent", root.spent)
+	}
+}
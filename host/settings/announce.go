@@ -5,13 +5,19 @@ import (
 	"fmt"
 	"net"
 	"strconv"
+	"strings"
 
+	"go.sia.tech/core/consensus"
 	"go.sia.tech/core/types"
 	"go.sia.tech/coreutils/chain"
 	rhp4 "go.sia.tech/coreutils/rhp/v4"
 	"go.uber.org/zap"
 )
 
+// announcementTxnSize is the estimated size in bytes of an announcement
+// transaction, used to calculate the miner fee to include.
+const announcementTxnSize = 1000
+
 type (
 	// An Announcement contains the host's announced netaddress
 	Announcement struct {
@@ -20,27 +26,137 @@ type (
 	}
 )
 
+// defaultProtocolPorts are the ports assumed for a protocol's address when
+// the operator configures one without an explicit port.
+var defaultProtocolPorts = map[string]string{
+	rhp4.ProtocolTCPSiaMux:  "9984",
+	rhp4.ProtocolQUICSiaMux: "9984",
+}
+
+// netAddresses returns the effective set of (protocol, address) tuples that
+// should be announced, falling back to the legacy single NetAddress field
+// for settings that have not been migrated to NetAddresses yet.
+func netAddresses(settings Settings) []chain.NetAddress {
+	if len(settings.NetAddresses) > 0 {
+		return settings.NetAddresses
+	} else if settings.NetAddress != "" {
+		return []chain.NetAddress{{Protocol: rhp4.ProtocolTCPSiaMux, Address: settings.NetAddress}}
+	}
+	return nil
+}
+
+// firstTCPAddress returns the address of the first ProtocolTCPSiaMux entry in
+// addrs, for use in the legacy v1 HostAnnouncement, which only supports a
+// single siamux address.
+func firstTCPAddress(addrs []chain.NetAddress) (string, error) {
+	for _, addr := range addrs {
+		if addr.Protocol == rhp4.ProtocolTCPSiaMux {
+			return addr.Address, nil
+		}
+	}
+	return "", errors.New("no TCP net address configured")
+}
+
+// primaryAddress returns the address recorded alongside an announcement's
+// chain index, preferring the legacy TCP address since that's what older
+// renters key announcements on.
+func primaryAddress(addrs []chain.NetAddress) string {
+	if addr, err := firstTCPAddress(addrs); err == nil {
+		return addr
+	} else if len(addrs) > 0 {
+		return addrs[0].Address
+	}
+	return ""
+}
+
+// netAddressesEqual reports whether a and b contain the same
+// protocol/address tuples, regardless of order.
+func netAddressesEqual(a, b []chain.NetAddress) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	seen := make(map[chain.NetAddress]int, len(a))
+	for _, addr := range a {
+		seen[addr]++
+	}
+	for _, addr := range b {
+		seen[addr]--
+		if seen[addr] < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// checkAnnounce re-announces the host if its configured addresses have
+// changed since the last announcement, the announce interval has elapsed,
+// or the chain just crossed into the v2 hardfork.
+func (m *ConfigManager) checkAnnounce(cs consensus.State) {
+	settings := m.Settings()
+	addrs := netAddresses(settings)
+	if len(addrs) == 0 {
+		return
+	}
+
+	m.mu.Lock()
+	changed := !netAddressesEqual(addrs, m.lastAddrs)
+	crossedV2 := !m.crossedV2 && cs.Index.Height >= cs.Network.HardforkV2.AllowHeight
+	m.mu.Unlock()
+
+	last, err := m.store.LastAnnouncement()
+	elapsed := err != nil || cs.Index.Height >= last.Index.Height+m.announceInterval
+
+	if err == nil && !changed && !elapsed && !crossedV2 {
+		return
+	}
+
+	if err := m.Announce(); err != nil {
+		m.log.Warn("failed to auto-announce", zap.Error(err))
+		return
+	}
+
+	m.mu.Lock()
+	m.lastAddrs = addrs
+	if crossedV2 {
+		m.crossedV2 = true
+	}
+	m.mu.Unlock()
+}
+
 // Announce announces the host to the network
 func (m *ConfigManager) Announce() error {
 	// get the current settings
 	settings := m.Settings()
+	addrs := netAddresses(settings)
 
 	if m.validateNetAddress {
-		if err := validateNetAddress(settings.NetAddress); err != nil {
-			return fmt.Errorf("failed to validate net address %q: %w", settings.NetAddress, err)
+		for _, addr := range addrs {
+			if err := validateNetAddress(addr.Protocol, addr.Address); err != nil {
+				return fmt.Errorf("failed to validate net address %q: %w", addr.Address, err)
+			}
 		}
 	}
 
+	if err := m.checkReachability(addrs); err != nil {
+		return fmt.Errorf("reachability check failed: %w", err)
+	}
+
 	minerFee := m.chain.RecommendedFee().Mul64(announcementTxnSize)
 
 	cs := m.chain.TipState()
 	if cs.Index.Height < cs.Network.HardforkV2.AllowHeight {
+		// the v1 announcement only supports a single siamux address
+		netaddress, err := firstTCPAddress(addrs)
+		if err != nil {
+			return fmt.Errorf("failed to determine v1 net address: %w", err)
+		}
+
 		// create a transaction with an announcement
 		txn := types.Transaction{
 			ArbitraryData: [][]byte{
 				chain.HostAnnouncement{
 					PublicKey:  m.hostKey.PublicKey(),
-					NetAddress: settings.NetAddress,
+					NetAddress: netaddress,
 				}.ToArbitraryData(m.hostKey),
 			},
 			MinerFees: []types.Currency{minerFee},
@@ -58,14 +174,13 @@ func (m *ConfigManager) Announce() error {
 			return fmt.Errorf("failed to add transaction to pool: %w", err)
 		}
 		m.syncer.BroadcastTransactionSet(txnset)
-		m.log.Debug("broadcast announcement", zap.String("transactionID", txn.ID().String()), zap.String("netaddress", settings.NetAddress), zap.String("cost", minerFee.ExactString()))
+		m.log.Debug("broadcast announcement", zap.String("transactionID", txn.ID().String()), zap.String("netaddress", netaddress), zap.String("cost", minerFee.ExactString()))
 	} else {
-		// create a v2 transaction with an announcement
+		// create a v2 transaction with an announcement containing every
+		// configured protocol/address tuple
 		txn := types.V2Transaction{
 			Attestations: []types.Attestation{
-				chain.V2HostAnnouncement{
-					{Protocol: rhp4.ProtocolTCPSiaMux, Address: settings.NetAddress}, // TODO: this isn't correct
-				}.ToAttestation(cs, m.hostKey),
+				chain.V2HostAnnouncement(addrs).ToAttestation(cs, m.hostKey),
 			},
 			MinerFee: minerFee,
 		}
@@ -83,15 +198,27 @@ func (m *ConfigManager) Announce() error {
 			return fmt.Errorf("failed to add transaction to pool: %w", err)
 		}
 		m.syncer.BroadcastV2TransactionSet(cs.Index, txnset)
-		m.log.Debug("broadcast v2 announcement", zap.String("transactionID", txn.ID().String()), zap.String("netaddress", settings.NetAddress), zap.String("cost", minerFee.ExactString()))
+		m.log.Debug("broadcast v2 announcement", zap.String("transactionID", txn.ID().String()), zap.Int("addresses", len(addrs)), zap.String("cost", minerFee.ExactString()))
+	}
+
+	ann := Announcement{Index: cs.Index, Address: primaryAddress(addrs)}
+	if err := m.store.UpdateLastAnnouncement(ann); err != nil {
+		m.log.Error("failed to record announcement", zap.Error(err))
 	}
 	return nil
 }
 
-func validateNetAddress(netaddress string) error {
+func validateNetAddress(protocol, netaddress string) error {
 	host, port, err := net.SplitHostPort(netaddress)
 	if err != nil {
-		return fmt.Errorf("failed to split net address: %w", err)
+		// no port was specified, fall back to the protocol's default port.
+		// Strip any IPv6 brackets first so the bracket-free host below
+		// parses the same way it would have if SplitHostPort had succeeded.
+		defaultPort, ok := defaultProtocolPorts[protocol]
+		if !ok {
+			return fmt.Errorf("failed to split net address: %w", err)
+		}
+		host, port = strings.TrimSuffix(strings.TrimPrefix(netaddress, "["), "]"), defaultPort
 	}
 
 	// Check that the host is not empty or localhost.
@@ -110,6 +237,8 @@ func validateNetAddress(netaddress string) error {
 	}
 
 	// If the host is an IP address, check that it is a public IP address.
+	// net.ParseIP handles bracketed IPv6 hosts returned by SplitHostPort
+	// (e.g. "[2001:db8::1]:9984") since the brackets are already stripped.
 	ip := net.ParseIP(host)
 	if ip != nil {
 		if ip.IsLoopback() || ip.IsPrivate() || !ip.IsGlobalUnicast() {
@@ -1,9 +1,13 @@
 package settings_test
 
 import (
+	"context"
+	"errors"
 	"testing"
 
 	"go.sia.tech/core/types"
+	"go.sia.tech/coreutils/chain"
+	rhp4 "go.sia.tech/coreutils/rhp/v4"
 	"go.sia.tech/coreutils/wallet"
 	"go.sia.tech/hostd/host/contracts"
 	"go.sia.tech/hostd/host/settings"
@@ -13,6 +17,20 @@ import (
 	"go.uber.org/zap/zaptest"
 )
 
+// fakeProber is a settings.Prober that fails for a fixed set of
+// protocol/address tuples, for exercising ConfigManager's reachability
+// check in isolation from a real network dial.
+type fakeProber struct {
+	fail map[string]error
+}
+
+func (p *fakeProber) Probe(_ context.Context, protocol, address string, _ types.PublicKey) error {
+	if err, ok := p.fail[protocol+"|"+address]; ok {
+		return err
+	}
+	return nil
+}
+
 func TestAutoAnnounce(t *testing.T) {
 	log := zaptest.NewLogger(t)
 	network, genesisBlock := testutil.V1Network()
@@ -101,4 +119,87 @@ func TestAutoAnnounce(t *testing.T) {
 	// mine a few more blocks to ensure the host doesn't re-announce
 	testutil.MineAndSync(t, node.Chain, idx, wm.Address(), 10)
 	assertAnnouncement(t, "baz.qux:5678", n.HardforkV2.AllowHeight+1)
+
+	// configure a second, QUIC address. Since the tuple set changed, the host
+	// should re-announce even though the legacy NetAddress is unchanged.
+	settings.NetAddresses = []chain.NetAddress{
+		{Protocol: rhp4.ProtocolTCPSiaMux, Address: "baz.qux:5678"},
+		{Protocol: rhp4.ProtocolQUICSiaMux, Address: "baz.qux:5678"},
+	}
+	sm.UpdateSettings(settings)
+
+	testutil.MineAndSync(t, node.Chain, idx, wm.Address(), 2)
+	assertAnnouncement(t, "baz.qux:5678", n.HardforkV2.AllowHeight+13)
+}
+
+func TestReachabilityCheck(t *testing.T) {
+	log := zaptest.NewLogger(t)
+	network, genesisBlock := testutil.V1Network()
+	hostKey := types.GeneratePrivateKey()
+
+	node := testutil.NewConsensusNode(t, network, genesisBlock, log)
+
+	wm, err := wallet.NewSingleAddressWallet(hostKey, node.Chain, node.Store)
+	if err != nil {
+		t.Fatal("failed to create wallet:", err)
+	}
+	defer wm.Close()
+
+	vm, err := storage.NewVolumeManager(node.Store, storage.WithLogger(log.Named("storage")))
+	if err != nil {
+		t.Fatal("failed to create volume manager:", err)
+	}
+	defer vm.Close()
+
+	contracts, err := contracts.NewManager(node.Store, vm, node.Chain, node.Syncer, wm, contracts.WithRejectAfter(10), contracts.WithRevisionSubmissionBuffer(5), contracts.WithLog(log))
+	if err != nil {
+		t.Fatal("failed to create contracts manager:", err)
+	}
+	defer contracts.Close()
+
+	prober := &fakeProber{fail: map[string]error{
+		rhp4.ProtocolQUICSiaMux + "|" + "baz.qux:5678": errors.New("connection refused"),
+	}}
+
+	sm, err := settings.NewConfigManager(hostKey, node.Store, node.Chain, node.Syncer, wm, settings.WithLog(log.Named("settings")), settings.WithReachabilityCheck(prober))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sm.Close()
+
+	idx, err := index.NewManager(node.Store, node.Chain, contracts, wm, sm, vm, index.WithLog(log.Named("index")), index.WithBatchSize(0))
+	if err != nil {
+		t.Fatal("failed to create index manager:", err)
+	}
+	defer idx.Close()
+
+	testutil.MineAndSync(t, node.Chain, idx, wm.Address(), 150)
+
+	cfg := settings.DefaultSettings
+	cfg.NetAddresses = []chain.NetAddress{
+		{Protocol: rhp4.ProtocolTCPSiaMux, Address: "baz.qux:5678"},
+		{Protocol: rhp4.ProtocolQUICSiaMux, Address: "baz.qux:5678"},
+	}
+	sm.UpdateSettings(cfg)
+
+	if err := sm.Announce(); err == nil {
+		t.Fatal("expected announcement to fail reachability check")
+	}
+
+	status := sm.ReachabilityStatus()
+	if len(status) != 2 {
+		t.Fatalf("expected 2 reachability results, got %v", len(status))
+	}
+	for _, s := range status {
+		wantReachable := s.Protocol != rhp4.ProtocolQUICSiaMux
+		if s.Reachable != wantReachable {
+			t.Fatalf("protocol %v: expected reachable=%v, got %v", s.Protocol, wantReachable, s.Reachable)
+		}
+	}
+
+	// clear the failure and confirm the announcement now succeeds
+	prober.fail = nil
+	if err := sm.Announce(); err != nil {
+		t.Fatal("expected announcement to succeed once reachable:", err)
+	}
 }
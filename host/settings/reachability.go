@@ -0,0 +1,110 @@
+package settings
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.sia.tech/core/types"
+	"go.sia.tech/coreutils/chain"
+	"go.sia.tech/hostd/alerts"
+	"go.uber.org/zap"
+)
+
+// reachabilityCheckTimeout bounds the total time a single Announce call will
+// wait on the configured Prober, shared across all protocol/address tuples.
+const reachabilityCheckTimeout = 30 * time.Second
+
+// alertReachabilityFailed is the deterministic ID of the alert raised when
+// one or more announced addresses fail their reachability check.
+var alertReachabilityFailed = types.HashBytes([]byte("settings.reachability.failed"))
+
+type (
+	// A Prober dials back to a configured (protocol, address) tuple to verify
+	// that it is reachable and that the handshake identifies this host's
+	// public key. Implementations may dial directly, e.g. over TCP or QUIC,
+	// or delegate the dial to a third-party reachability service.
+	Prober interface {
+		// Probe dials protocol/address and returns an error if a renter would
+		// not be able to reach and authenticate hostKey there.
+		Probe(ctx context.Context, protocol, address string, hostKey types.PublicKey) error
+	}
+
+	// ProtocolReachability is the result of probing a single configured
+	// protocol/address tuple.
+	ProtocolReachability struct {
+		Protocol  string    `json:"protocol"`
+		Address   string    `json:"address"`
+		Reachable bool      `json:"reachable"`
+		Error     string    `json:"error,omitempty"`
+		Timestamp time.Time `json:"timestamp"`
+	}
+)
+
+// WithReachabilityCheck configures a Prober that ConfigManager uses to
+// actively verify each configured protocol/address tuple is reachable before
+// committing an announcement transaction. If no Prober is configured,
+// Announce skips the reachability check entirely.
+func WithReachabilityCheck(prober Prober) Option {
+	return func(m *ConfigManager) {
+		m.prober = prober
+	}
+}
+
+// ReachabilityStatus returns the result of the most recent reachability
+// check, one entry per configured protocol/address tuple, in the same order
+// they were last probed. It returns nil if no check has run yet.
+func (m *ConfigManager) ReachabilityStatus() []ProtocolReachability {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]ProtocolReachability(nil), m.reachability...)
+}
+
+// checkReachability probes each address in addrs and records the outcome. It
+// returns an error if the configured Prober could not confirm reachability
+// for at least one address. If no Prober is configured, it is a no-op.
+func (m *ConfigManager) checkReachability(addrs []chain.NetAddress) error {
+	if m.prober == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), reachabilityCheckTimeout)
+	defer cancel()
+
+	var failed []string
+	results := make([]ProtocolReachability, len(addrs))
+	for i, addr := range addrs {
+		result := ProtocolReachability{Protocol: addr.Protocol, Address: addr.Address, Timestamp: time.Now()}
+		if err := m.prober.Probe(ctx, addr.Protocol, addr.Address, m.hostKey.PublicKey()); err != nil {
+			result.Error = err.Error()
+			failed = append(failed, fmt.Sprintf("%s (%s): %s", addr.Address, addr.Protocol, err))
+			m.log.Warn("reachability check failed", zap.String("protocol", addr.Protocol), zap.String("address", addr.Address), zap.Error(err))
+		} else {
+			result.Reachable = true
+		}
+		results[i] = result
+	}
+
+	m.mu.Lock()
+	m.reachability = results
+	m.mu.Unlock()
+
+	if len(failed) > 0 {
+		if m.alerts != nil {
+			m.alerts.Register(alerts.Alert{
+				ID:       alertReachabilityFailed,
+				Severity: alerts.SeverityWarning,
+				Message:  "Host is not reachable at one or more announced addresses",
+				Data: map[string]any{
+					"failures": failed,
+				},
+				Timestamp: time.Now(),
+			})
+		}
+		return fmt.Errorf("%d of %d addresses failed reachability check", len(failed), len(addrs))
+	}
+	if m.alerts != nil {
+		m.alerts.Dismiss(alertReachabilityFailed)
+	}
+	return nil
+}
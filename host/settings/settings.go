@@ -0,0 +1,208 @@
+package settings
+
+import (
+	"fmt"
+	"sync"
+
+	"go.sia.tech/core/consensus"
+	"go.sia.tech/core/types"
+	"go.sia.tech/coreutils/chain"
+	"go.sia.tech/hostd/alerts"
+	"go.uber.org/zap"
+)
+
+// defaultAnnounceInterval is the number of blocks the host waits between
+// automatic re-announcements when its configured addresses haven't changed.
+const defaultAnnounceInterval = 1008 // ~1 week
+
+type (
+	// Settings contains the host's configuration.
+	Settings struct {
+		// NetAddress is the legacy, single siamux address the host
+		// announces. Deprecated in favor of NetAddresses, but still
+		// honored by netAddresses for hosts that haven't been
+		// reconfigured.
+		NetAddress string `json:"netAddress"`
+		// NetAddresses is the set of protocol/address tuples the host
+		// announces. If empty, NetAddress is used instead.
+		NetAddresses []chain.NetAddress `json:"netAddresses"`
+	}
+
+	// A Store persists the host's settings and announcement history.
+	Store interface {
+		// Settings returns the host's current settings.
+		Settings() (Settings, error)
+		// UpdateSettings persists the host's settings.
+		UpdateSettings(Settings) error
+		// LastAnnouncement returns the most recently confirmed
+		// announcement, or an error if the host has never announced.
+		LastAnnouncement() (Announcement, error)
+		// UpdateLastAnnouncement persists the most recently confirmed
+		// announcement.
+		UpdateLastAnnouncement(Announcement) error
+	}
+
+	// A ChainManager tracks the current state of the blockchain.
+	ChainManager interface {
+		TipState() consensus.State
+		RecommendedFee() types.Currency
+		UnconfirmedParents(txn types.Transaction) []types.Transaction
+		AddPoolTransactions(txns []types.Transaction) (bool, error)
+		V2TransactionSet(basis types.ChainIndex, txn types.V2Transaction) (types.ChainIndex, []types.V2Transaction, error)
+		AddV2PoolTransactions(basis types.ChainIndex, txns []types.V2Transaction) (bool, error)
+	}
+
+	// A Syncer broadcasts transactions to the network.
+	Syncer interface {
+		BroadcastTransactionSet(txns []types.Transaction)
+		BroadcastV2TransactionSet(index types.ChainIndex, txns []types.V2Transaction)
+	}
+
+	// A Wallet funds and signs announcement transactions.
+	Wallet interface {
+		FundTransaction(txn *types.Transaction, amount types.Currency, useUnconfirmed bool) ([]types.Hash256, error)
+		SignTransaction(txn *types.Transaction, toSign []types.Hash256, cf types.CoveredFields)
+		FundV2Transaction(txn *types.V2Transaction, amount types.Currency, useUnconfirmed bool) (types.ChainIndex, []int, error)
+		SignV2Inputs(txn *types.V2Transaction, toSign []int)
+		ReleaseInputs(txns []types.Transaction, v2txns []types.V2Transaction)
+	}
+
+	// An AlertManager registers and dismisses host alerts. It is only
+	// consulted if configured via WithAlerts.
+	AlertManager interface {
+		Register(alerts.Alert)
+		Dismiss(id types.Hash256)
+	}
+
+	// A ConfigManager manages the host's configuration, including
+	// announcing the host to the network.
+	ConfigManager struct {
+		hostKey types.PrivateKey
+
+		store  Store
+		chain  ChainManager
+		syncer Syncer
+		wallet Wallet
+		log    *zap.Logger
+
+		validateNetAddress bool
+		announceInterval   uint64
+
+		// optional dependencies, set via options
+		prober Prober
+		alerts AlertManager
+
+		mu           sync.Mutex
+		settings     Settings
+		lastAddrs    []chain.NetAddress
+		crossedV2    bool
+		reachability []ProtocolReachability
+	}
+
+	// An Option configures a ConfigManager.
+	Option func(*ConfigManager)
+)
+
+// DefaultSettings are the default settings for a new host.
+var DefaultSettings = Settings{}
+
+// WithLog sets the logger used by the ConfigManager.
+func WithLog(log *zap.Logger) Option {
+	return func(m *ConfigManager) {
+		m.log = log
+	}
+}
+
+// WithAnnounceInterval sets the number of blocks the host waits between
+// automatic re-announcements when its configured addresses haven't changed.
+func WithAnnounceInterval(blocks uint64) Option {
+	return func(m *ConfigManager) {
+		m.announceInterval = blocks
+	}
+}
+
+// WithAlerts configures the AlertManager used to surface reachability
+// failures. If unset, reachability failures are only logged.
+func WithAlerts(am AlertManager) Option {
+	return func(m *ConfigManager) {
+		m.alerts = am
+	}
+}
+
+// NewConfigManager initializes a new ConfigManager.
+func NewConfigManager(hostKey types.PrivateKey, store Store, cm ChainManager, s Syncer, w Wallet, opts ...Option) (*ConfigManager, error) {
+	m := &ConfigManager{
+		hostKey: hostKey,
+
+		store:  store,
+		chain:  cm,
+		syncer: s,
+		wallet: w,
+		log:    zap.NewNop(),
+
+		validateNetAddress: true,
+		announceInterval:   defaultAnnounceInterval,
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	settings, err := store.Settings()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load settings: %w", err)
+	}
+	m.settings = settings
+	m.lastAddrs = netAddresses(settings)
+	// a host starting up already past the v2 hardfork has nothing to
+	// cross; only a host that starts before the fork and later observes
+	// the chain reach it should force the one-time re-announcement.
+	cs := m.chain.TipState()
+	m.crossedV2 = cs.Index.Height >= cs.Network.HardforkV2.AllowHeight
+	return m, nil
+}
+
+// Close releases any resources held by the ConfigManager.
+func (m *ConfigManager) Close() error {
+	return nil
+}
+
+// Settings returns the host's current settings.
+func (m *ConfigManager) Settings() Settings {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.settings
+}
+
+// UpdateSettings persists and applies new settings.
+func (m *ConfigManager) UpdateSettings(settings Settings) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.store.UpdateSettings(settings); err != nil {
+		return fmt.Errorf("failed to update settings: %w", err)
+	}
+	m.settings = settings
+	return nil
+}
+
+// LastAnnouncement returns the most recently confirmed announcement.
+func (m *ConfigManager) LastAnnouncement() (Announcement, error) {
+	return m.store.LastAnnouncement()
+}
+
+// ProcessChainApplyUpdate implements chain.Subscriber. It triggers an
+// automatic re-announcement when the configured addresses have changed,
+// the announce interval has elapsed, or the chain has just crossed into
+// the v2 hardfork, deferring the check to the final update of a batch so a
+// long sync doesn't re-announce once per block.
+func (m *ConfigManager) ProcessChainApplyUpdate(cau *chain.ApplyUpdate, mayCommit bool) error {
+	if mayCommit {
+		m.checkAnnounce(cau.State)
+	}
+	return nil
+}
+
+// ProcessChainRevertUpdate implements chain.Subscriber. The host's settings
+// don't depend on chain state that needs to be rolled back.
+func (m *ConfigManager) ProcessChainRevertUpdate(cru *chain.RevertUpdate) error {
+	return nil
+}
@@ -120,4 +120,121 @@ func TestUpdateContractRoots(t *testing.T) {
 	} else if err = rootsEqual(roots, dbRoots); err != nil {
 		t.Fatal(err)
 	}
-}
\ No newline at end of file
+
+	// append a batch of sectors in one call
+	batch := make([]crypto.Hash, 5)
+	for i := range batch {
+		batch[i] = frand.Entropy256()
+	}
+	err = db.UpdateContracts(func(tx contracts.UpdateContractTransaction) error {
+		return tx.AppendSectors(contract.ParentID, batch)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	roots = append(roots, batch...)
+
+	dbRoots, err = db.SectorRoots(contract.ParentID, 0, 100)
+	if err != nil {
+		t.Fatal(err)
+	} else if err = rootsEqual(roots, dbRoots); err != nil {
+		t.Fatal(err)
+	}
+
+	// swap a range of sectors in one call
+	i, rangeLen := 0, 3
+	j := len(roots) - rangeLen
+	err = db.UpdateContracts(func(tx contracts.UpdateContractTransaction) error {
+		return tx.SwapSectorRanges(contract.ParentID, uint64(i), uint64(j), uint64(rangeLen))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for k := 0; k < rangeLen; k++ {
+		roots[i+k], roots[j+k] = roots[j+k], roots[i+k]
+	}
+
+	dbRoots, err = db.SectorRoots(contract.ParentID, 0, 100)
+	if err != nil {
+		t.Fatal(err)
+	} else if err = rootsEqual(roots, dbRoots); err != nil {
+		t.Fatal(err)
+	}
+
+	// overlapping ranges should fail
+	err = db.UpdateContracts(func(tx contracts.UpdateContractTransaction) error {
+		return tx.SwapSectorRanges(contract.ParentID, 0, 2, 5)
+	})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+
+	// verify a Merkle proof for every sector root against the root computed
+	// directly from the full set of roots
+	for idx := range roots {
+		proof, err := db.SectorRootProof(contract.ParentID, uint64(idx))
+		if err != nil {
+			t.Fatal(err)
+		}
+		// The expected root is computed directly from the production
+		// subtreeRoot rather than a re-derivation of it here, so this only
+		// exercises SectorRootProof's indexing against itself, not its
+		// hashing scheme. There is no core rhp package vendored into this
+		// tree to import and compare against, so this test cannot catch a
+		// systemic hashing mismatch (wrong prefix byte, wrong concatenation
+		// order) against a real renter's implementation - only a fixture
+		// with a known-good root from core's rhp package could close that
+		// gap.
+		if err := verifyProof(roots[idx], uint64(idx), uint64(len(roots)), proof, subtreeRoot(roots)); err != nil {
+			t.Fatalf("sector %v: %v", idx, err)
+		}
+	}
+}
+
+// verifyProof independently folds proof against leaf by walking the same
+// power-of-two split points SectorRootProof used to build it, but hashing
+// siblings back together bottom-up instead of top-down, and checks the
+// result against root.
+func verifyProof(leaf crypto.Hash, index, numLeaves uint64, proof []crypto.Hash, root crypto.Hash) error {
+	got, err := foldProof(leaf, index, numLeaves, proof)
+	if err != nil {
+		return err
+	} else if got != root {
+		return errors.New("proof did not verify against the expected root")
+	}
+	return nil
+}
+
+func foldProof(leaf crypto.Hash, index, numLeaves uint64, proof []crypto.Hash) (crypto.Hash, error) {
+	if numLeaves == 1 {
+		if len(proof) != 0 {
+			return crypto.Hash{}, errors.New("unexpected proof length for single-leaf tree")
+		}
+		return leaf, nil
+	}
+
+	mid := uint64(1)
+	for mid*2 < numLeaves {
+		mid *= 2
+	}
+	if len(proof) == 0 {
+		return crypto.Hash{}, errors.New("proof too short")
+	}
+	sibling, rest := proof[len(proof)-1], proof[:len(proof)-1]
+
+	var node crypto.Hash
+	if index < mid {
+		left, err := foldProof(leaf, index, mid, rest)
+		if err != nil {
+			return crypto.Hash{}, err
+		}
+		node = crypto.HashBytes(append([]byte{nodeHashPrefix}, append(left[:], sibling[:]...)...))
+	} else {
+		right, err := foldProof(leaf, index-mid, numLeaves-mid, rest)
+		if err != nil {
+			return crypto.Hash{}, err
+		}
+		node = crypto.HashBytes(append([]byte{nodeHashPrefix}, append(sibling[:], right[:]...)...))
+	}
+	return node, nil
+}